@@ -0,0 +1,107 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var meter = otel.Meter("oxia-server")
+
+// Labels is a convenience alias for the set of attributes attached to a metric, eg.
+// Labels{"shard": shardId}.
+type Labels map[string]any
+
+func toAttributes(labels Labels) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(labels))
+	for k, v := range labels {
+		switch value := v.(type) {
+		case string:
+			attrs = append(attrs, attribute.String(k, value))
+		case bool:
+			attrs = append(attrs, attribute.Bool(k, value))
+		case int:
+			attrs = append(attrs, attribute.Int(k, value))
+		case int64:
+			attrs = append(attrs, attribute.Int64(k, value))
+		case uint32:
+			attrs = append(attrs, attribute.Int64(k, int64(value)))
+		default:
+			attrs = append(attrs, attribute.String(k, "<unsupported>"))
+		}
+	}
+	return attrs
+}
+
+// Gauge is a point-in-time value, eg. the current size of a queue.
+type Gauge struct {
+	instrument metric.Int64Gauge
+	attrs      metric.MeasurementOption
+}
+
+// NewGauge registers a new Gauge. Panics if the underlying OTel instrument cannot be
+// created, matching the rest of the metrics package: a malformed metric is a programming
+// error, not something callers should need to handle.
+func NewGauge(name, description string, unit Unit, labels Labels) Gauge {
+	instrument, err := meter.Int64Gauge(name, metric.WithDescription(description), metric.WithUnit(string(unit)))
+	if err != nil {
+		panic(err)
+	}
+	return Gauge{instrument: instrument, attrs: metric.WithAttributes(toAttributes(labels)...)}
+}
+
+func (g Gauge) Set(value int64) {
+	g.instrument.Record(context.Background(), value, g.attrs)
+}
+
+// Counter is a monotonically increasing value, eg. the number of acks received.
+type Counter struct {
+	instrument metric.Int64Counter
+	attrs      metric.MeasurementOption
+}
+
+func NewCounter(name, description string, unit Unit, labels Labels) Counter {
+	instrument, err := meter.Int64Counter(name, metric.WithDescription(description), metric.WithUnit(string(unit)))
+	if err != nil {
+		panic(err)
+	}
+	return Counter{instrument: instrument, attrs: metric.WithAttributes(toAttributes(labels)...)}
+}
+
+func (c Counter) Add(delta int64) {
+	c.instrument.Add(context.Background(), delta, c.attrs)
+}
+
+// LatencyHistogram records a distribution of durations, in Milliseconds.
+type LatencyHistogram struct {
+	instrument metric.Float64Histogram
+	attrs      metric.MeasurementOption
+}
+
+func NewLatencyHistogram(name, description string, labels Labels) LatencyHistogram {
+	instrument, err := meter.Float64Histogram(name, metric.WithDescription(description), metric.WithUnit(string(Milliseconds)))
+	if err != nil {
+		panic(err)
+	}
+	return LatencyHistogram{instrument: instrument, attrs: metric.WithAttributes(toAttributes(labels)...)}
+}
+
+func (h LatencyHistogram) Record(milliseconds float64) {
+	h.instrument.Record(context.Background(), milliseconds, h.attrs)
+}
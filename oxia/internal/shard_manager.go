@@ -28,8 +28,12 @@ type shardManagerImpl struct {
 	clientPool     common.ClientPool
 	serviceAddress string
 	shards         map[uint32]Shard
-	closeC         chan bool
-	logger         zerolog.Logger
+	// shardsSlice mirrors shards as a slice, kept up to date in update(), since
+	// ShardStrategy.AssignShard takes a slice rather than forcing every implementation
+	// to deal with the map.
+	shardsSlice []Shard
+	closeC      chan bool
+	logger      zerolog.Logger
 }
 
 func NewShardManager(shardStrategy ShardStrategy, clientPool common.ClientPool, serviceAddress string) ShardManager {
@@ -74,14 +78,7 @@ func (s *shardManagerImpl) Get(key string) uint32 {
 	s.Lock()
 	defer s.Unlock()
 
-	predicate := s.shardStrategy.Get(key)
-
-	for _, shard := range s.shards {
-		if predicate(shard) {
-			return shard.Id
-		}
-	}
-	panic("shard not found")
+	return s.shardStrategy.AssignShard(key, s.shardsSlice)
 }
 
 func (s *shardManagerImpl) GetAll() []uint32 {
@@ -186,6 +183,12 @@ func (s *shardManagerImpl) update(updates []Shard) {
 		}
 		s.shards[update.Id] = update
 	}
+
+	s.shardsSlice = make([]Shard, 0, len(s.shards))
+	for _, shard := range s.shards {
+		s.shardsSlice = append(s.shardsSlice, shard)
+	}
+	s.shardStrategy.Rebuild(s.shardsSlice)
 }
 
 func overlap(a HashRange, b HashRange) bool {
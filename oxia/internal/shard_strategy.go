@@ -0,0 +1,236 @@
+package internal
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/twmb/murmur3"
+)
+
+// ShardStrategy assigns a key to one of the currently known shards.
+//
+// Implementations are free to maintain their own index (a sorted range tree, a
+// consistent-hash ring, ...) rather than scanning `shards` on every AssignShard call:
+// Rebuild is invoked by the ShardManager whenever the set of shards changes, before any
+// AssignShard call that should observe the new assignment.
+type ShardStrategy interface {
+	// AssignShard returns the id of the shard that owns `key`, out of `shards`.
+	AssignShard(key string, shards []Shard) uint32
+
+	// Rebuild recomputes whatever index the strategy maintains for `shards`.
+	Rebuild(shards []Shard)
+}
+
+func hashKey(key string) uint32 {
+	return murmur3.Sum32([]byte(key))
+}
+
+// hashRangeShardStrategy assigns a key to the shard whose HashRange contains
+// murmur3(key), keeping the shards sorted by range so that AssignShard is a binary
+// search instead of a linear scan.
+type hashRangeShardStrategy struct {
+	mu     sync.RWMutex
+	sorted []Shard
+}
+
+// NewHashRangeShardStrategy returns the default ShardStrategy, which partitions the
+// keyspace by hashing each key into a shard's HashRange.
+func NewHashRangeShardStrategy() ShardStrategy {
+	return &hashRangeShardStrategy{}
+}
+
+func (h *hashRangeShardStrategy) Rebuild(shards []Shard) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.sorted = sortedByHashRange(shards)
+}
+
+func (h *hashRangeShardStrategy) AssignShard(key string, shards []Shard) uint32 {
+	h.mu.RLock()
+	sorted := h.sorted
+	h.mu.RUnlock()
+
+	if sorted == nil {
+		// Rebuild hasn't run yet: fall back to sorting what we were given.
+		sorted = sortedByHashRange(shards)
+	}
+
+	return shardForPosition(sorted, hashKey(key))
+}
+
+// rangeShardStrategy assigns a key to a shard by the key itself, read as a big-endian
+// integer, rather than a hash of the key. Unlike hashing, this preserves the
+// lexicographic order of keys, so shard boundaries can be used for ordered range scans
+// across the cluster.
+//
+// A shard's HashRange is meaningless here: it's the boundary of a hash-space partition
+// computed for hashRangeShardStrategy, not a key-space boundary, and reusing it would
+// route keys against ranges that have no relationship to their raw bytes. Instead,
+// rangeShardStrategy carves up the raw key space itself into one contiguous range per
+// shard, ordered by shard id.
+type rangeShardStrategy struct {
+	mu         sync.RWMutex
+	boundaries []rangeBoundary // sorted by maxInclusive
+}
+
+// rangeBoundary is the upper bound (inclusive) of the key-space slice owned by shardId.
+type rangeBoundary struct {
+	maxInclusive uint32
+	shardId      uint32
+}
+
+// NewRangeShardStrategy returns a ShardStrategy that partitions the keyspace by the raw
+// key value instead of its hash, so that ordered scans can be routed shard-by-shard.
+func NewRangeShardStrategy() ShardStrategy {
+	return &rangeShardStrategy{}
+}
+
+func (r *rangeShardStrategy) Rebuild(shards []Shard) {
+	boundaries := rangeBoundariesFor(shards)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.boundaries = boundaries
+}
+
+func (r *rangeShardStrategy) AssignShard(key string, shards []Shard) uint32 {
+	r.mu.RLock()
+	boundaries := r.boundaries
+	r.mu.RUnlock()
+
+	if boundaries == nil {
+		// Rebuild hasn't run yet: fall back to partitioning what we were given.
+		boundaries = rangeBoundariesFor(shards)
+	}
+	if len(boundaries) == 0 {
+		panic("shard not found")
+	}
+
+	position := keyPosition(key)
+	idx := sort.Search(len(boundaries), func(i int) bool { return boundaries[i].maxInclusive >= position })
+	if idx == len(boundaries) {
+		// The last boundary is always the top of the key space, so this only guards
+		// against an out-of-range index: position belongs to the last shard's range.
+		idx = len(boundaries) - 1
+	}
+	return boundaries[idx].shardId
+}
+
+// rangeBoundariesFor splits the full uint32 key-space evenly across shards, ordered by
+// shard id so that the assignment is deterministic and contiguous.
+func rangeBoundariesFor(shards []Shard) []rangeBoundary {
+	if len(shards) == 0 {
+		return nil
+	}
+
+	sorted := make([]Shard, len(shards))
+	copy(sorted, shards)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Id < sorted[j].Id })
+
+	const keySpaceSize = uint64(1) << 32
+	width := keySpaceSize / uint64(len(sorted))
+
+	boundaries := make([]rangeBoundary, len(sorted))
+	for i, shard := range sorted {
+		maxInclusive := width*uint64(i+1) - 1
+		if i == len(sorted)-1 {
+			// Give the last shard any remainder left over by the integer division.
+			maxInclusive = keySpaceSize - 1
+		}
+		boundaries[i] = rangeBoundary{maxInclusive: uint32(maxInclusive), shardId: shard.Id}
+	}
+	return boundaries
+}
+
+// keyPosition maps a key to a uint32 position that preserves the key's lexicographic
+// order, by reading its first 4 bytes as a big-endian integer (shorter keys are
+// zero-padded).
+func keyPosition(key string) uint32 {
+	var buf [4]byte
+	copy(buf[:], key)
+	return binary.BigEndian.Uint32(buf[:])
+}
+
+func sortedByHashRange(shards []Shard) []Shard {
+	sorted := make([]Shard, len(shards))
+	copy(sorted, shards)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].HashRange.MinInclusive < sorted[j].HashRange.MinInclusive
+	})
+	return sorted
+}
+
+func shardForPosition(sorted []Shard, position uint32) uint32 {
+	idx := sort.Search(len(sorted), func(i int) bool {
+		return sorted[i].HashRange.MaxInclusive >= position
+	})
+	if idx < len(sorted) && sorted[idx].HashRange.MinInclusive <= position {
+		return sorted[idx].Id
+	}
+	panic("shard not found")
+}
+
+// consistentHashShardStrategy is a Ketama-style consistent-hash ring: each shard owns a
+// configurable number of virtual nodes scattered around the ring, which minimizes key
+// movement when shards split or merge compared to a plain hash-range partition.
+type consistentHashShardStrategy struct {
+	virtualNodesPerShard int
+
+	mu   sync.RWMutex
+	ring []ringNode
+}
+
+type ringNode struct {
+	hash    uint32
+	shardId uint32
+}
+
+// NewConsistentHashShardStrategy returns a Ketama-style consistent-hash ShardStrategy,
+// with `virtualNodesPerShard` virtual nodes per shard to smooth out the distribution of
+// the keyspace across shards.
+func NewConsistentHashShardStrategy(virtualNodesPerShard int) ShardStrategy {
+	if virtualNodesPerShard <= 0 {
+		virtualNodesPerShard = 100
+	}
+	return &consistentHashShardStrategy{virtualNodesPerShard: virtualNodesPerShard}
+}
+
+func (c *consistentHashShardStrategy) Rebuild(shards []Shard) {
+	ring := make([]ringNode, 0, len(shards)*c.virtualNodesPerShard)
+	for _, shard := range shards {
+		for v := 0; v < c.virtualNodesPerShard; v++ {
+			vNodeKey := fmt.Sprintf("%d-%d", shard.Id, v)
+			ring = append(ring, ringNode{hash: hashKey(vNodeKey), shardId: shard.Id})
+		}
+	}
+	sort.Slice(ring, func(i, j int) bool { return ring[i].hash < ring[j].hash })
+
+	c.mu.Lock()
+	c.ring = ring
+	c.mu.Unlock()
+}
+
+func (c *consistentHashShardStrategy) AssignShard(key string, shards []Shard) uint32 {
+	c.mu.RLock()
+	ring := c.ring
+	c.mu.RUnlock()
+
+	if len(ring) == 0 {
+		if len(shards) == 0 {
+			panic("shard not found")
+		}
+		return shards[0].Id
+	}
+
+	hash := hashKey(key)
+	idx := sort.Search(len(ring), func(i int) bool { return ring[i].hash >= hash })
+	if idx == len(ring) {
+		// Wrap around the ring back to the first node.
+		idx = 0
+	}
+	return ring[idx].shardId
+}
@@ -0,0 +1,133 @@
+package internal
+
+import "testing"
+
+func shards3() []Shard {
+	return []Shard{
+		{Id: 0, HashRange: HashRange{MinInclusive: 0, MaxInclusive: 999}},
+		{Id: 1, HashRange: HashRange{MinInclusive: 1000, MaxInclusive: 1999}},
+		{Id: 2, HashRange: HashRange{MinInclusive: 2000, MaxInclusive: 2999}},
+	}
+}
+
+func TestHashRangeShardStrategy_ConsistentWithItself(t *testing.T) {
+	strategy := NewHashRangeShardStrategy()
+	shards := shards3()
+	strategy.Rebuild(shards)
+
+	// Assigning the same key twice must return the same shard.
+	first := strategy.AssignShard("some-key", shards)
+	second := strategy.AssignShard("some-key", shards)
+	if first != second {
+		t.Errorf("expected repeated assignment of the same key to be stable, got %d then %d", first, second)
+	}
+}
+
+func TestConsistentHashShardStrategy_StableAssignment(t *testing.T) {
+	strategy := NewConsistentHashShardStrategy(100)
+	shards := shards3()
+	strategy.Rebuild(shards)
+
+	keys := []string{"alpha", "bravo", "charlie", "delta", "echo"}
+	assignments := make(map[string]uint32, len(keys))
+	for _, key := range keys {
+		assignments[key] = strategy.AssignShard(key, shards)
+	}
+
+	// Rebuilding with the same shard set must not change any key's assignment.
+	strategy.Rebuild(shards)
+	for _, key := range keys {
+		if got := strategy.AssignShard(key, shards); got != assignments[key] {
+			t.Errorf("expected key %q to stay on shard %d after a no-op rebuild, got %d", key, assignments[key], got)
+		}
+	}
+}
+
+func TestConsistentHashShardStrategy_MinimalMovementOnShardRemoval(t *testing.T) {
+	strategy := NewConsistentHashShardStrategy(100)
+	shards := shards3()
+	strategy.Rebuild(shards)
+
+	keys := make([]string, 200)
+	before := make(map[string]uint32, len(keys))
+	for i := range keys {
+		keys[i] = string(rune('a' + i%26))
+		keys[i] += string(rune('0' + i/26))
+		before[keys[i]] = strategy.AssignShard(keys[i], shards)
+	}
+
+	// Remove one shard: only the keys that were assigned to it should move.
+	remaining := shards[:2]
+	strategy.Rebuild(remaining)
+
+	moved := 0
+	for _, key := range keys {
+		after := strategy.AssignShard(key, remaining)
+		if before[key] != shards[2].Id && after != before[key] {
+			t.Errorf("key %q assigned to a surviving shard moved from %d to %d", key, before[key], after)
+		}
+		if after != before[key] {
+			moved++
+		}
+	}
+	if moved == 0 {
+		t.Error("expected at least the keys from the removed shard to move")
+	}
+	if moved > len(keys)/2 {
+		t.Errorf("expected consistent hashing to move roughly 1/3 of keys, moved %d of %d", moved, len(keys))
+	}
+}
+
+func TestRangeShardStrategy_PreservesKeyOrder(t *testing.T) {
+	strategy := NewRangeShardStrategy()
+	shards := shards3()
+	strategy.Rebuild(shards)
+
+	// Keys in increasing lexicographic order must land on a non-decreasing sequence of
+	// shard ids, since the whole point of this strategy is ordered range scans. Leading
+	// bytes are spread across the whole uint32 space so the keys actually land on
+	// different shards.
+	keys := []string{
+		string([]byte{0x00, 0, 0, 0}),
+		string([]byte{0x10, 0, 0, 0}),
+		string([]byte{0x40, 0, 0, 0}),
+		string([]byte{0x60, 0, 0, 0}),
+		string([]byte{0x90, 0, 0, 0}),
+		string([]byte{0xc0, 0, 0, 0}),
+		string([]byte{0xe0, 0, 0, 0}),
+		string([]byte{0xff, 0xff, 0xff, 0xff}),
+	}
+
+	last := uint32(0)
+	seenShards := map[uint32]bool{}
+	for i, key := range keys {
+		got := strategy.AssignShard(key, shards)
+		seenShards[got] = true
+		if i > 0 && got < last {
+			t.Errorf("expected shard ids to be non-decreasing for increasing keys, got %d after %d at key %q", got, last, key)
+		}
+		last = got
+	}
+	if len(seenShards) < 2 {
+		t.Errorf("expected keys spanning the whole key space to land on more than one shard, got %v", seenShards)
+	}
+}
+
+func TestRangeShardStrategy_DoesNotReuseHashRange(t *testing.T) {
+	strategy := NewRangeShardStrategy()
+	// Shards whose HashRange covers the whole uint32 space unevenly, so that if the
+	// strategy were (incorrectly) using HashRange boundaries directly, low keys would
+	// all land on shard 0.
+	shards := []Shard{
+		{Id: 0, HashRange: HashRange{MinInclusive: 0, MaxInclusive: 4000000000}},
+		{Id: 1, HashRange: HashRange{MinInclusive: 4000000001, MaxInclusive: 4294967295}},
+	}
+	strategy.Rebuild(shards)
+
+	// 0xc0000000 lies inside shard 0's HashRange, but in the upper half of the key space:
+	// with an even key-space split (not a HashRange split) it should land on shard 1.
+	got := strategy.AssignShard("\xc0\x00\x00\x00", shards)
+	if got != 1 {
+		t.Errorf("expected a key-space split independent of HashRange, got shard %d for a high key", got)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import "github.com/streamnative/oxia/server/util"
+
+// ackRingInitialCapacity is the initial number of slots in a freshly created ackRing.
+// It grows (by doubling) whenever the in-flight window of un-committed offsets outgrows it.
+const ackRingInitialCapacity = 256
+
+// ackRing tracks the per-entry ack BitSet for the sliding window of offsets that are
+// between the commit offset and the head offset, ie. written but not yet known to be
+// fully committed.
+//
+// It replaces a map[int64]*util.BitSet: offsets are always pushed in increasing order and,
+// in the common case, removed in roughly that same order, so a circular buffer indexed by
+// `offset - base` avoids a map allocation per entry and lets the window slide forward in
+// O(1) amortized time instead of deleting map keys one by one.
+type ackRing struct {
+	entries []*util.BitSet
+	head    int   // index of the slot holding `base`
+	base    int64 // offset tracked at entries[head]
+	count   int   // number of occupied slots, including slots nilled out ahead of `head`
+	live    int   // number of offsets actually still pending quorum, ie. count minus holes
+}
+
+func newAckRing() *ackRing {
+	return &ackRing{entries: make([]*util.BitSet, ackRingInitialCapacity)}
+}
+
+// Push records a new offset to track, which must be the next offset after the last one
+// pushed (ie. base+count).
+func (r *ackRing) Push(offset int64, bs *util.BitSet) {
+	if r.count == 0 {
+		r.base = offset
+	}
+	if r.count == len(r.entries) {
+		r.grow()
+	}
+	r.entries[(r.head+r.count)%len(r.entries)] = bs
+	r.count++
+	r.live++
+}
+
+// Live returns the number of offsets still actually pending quorum, unlike count (which
+// also includes holes left by offsets removed out of order that haven't slid out of the
+// window yet).
+func (r *ackRing) Live() int {
+	return r.live
+}
+
+// Get returns the BitSet tracking acks for offset, if it's still pending.
+func (r *ackRing) Get(offset int64) (*util.BitSet, bool) {
+	if r.count == 0 || offset < r.base || offset >= r.base+int64(r.count) {
+		return nil, false
+	}
+	bs := r.entries[(r.head+int(offset-r.base))%len(r.entries)]
+	return bs, bs != nil
+}
+
+// Remove drops offset from the window, since it has reached quorum. If offset is the
+// oldest tracked entry, the window slides forward past it and past any later offset that
+// had already been removed out of order, so the common in-order case is O(1) per call and
+// the window never accumulates stale nil slots at its head.
+func (r *ackRing) Remove(offset int64) {
+	if r.count == 0 || offset < r.base || offset >= r.base+int64(r.count) {
+		return
+	}
+
+	idx := (r.head + int(offset-r.base)) % len(r.entries)
+	if r.entries[idx] == nil {
+		return
+	}
+	r.entries[idx] = nil
+	r.live--
+	if idx != r.head {
+		return
+	}
+
+	for r.count > 0 && r.entries[r.head] == nil {
+		r.entries[r.head] = nil
+		r.head = (r.head + 1) % len(r.entries)
+		r.base++
+		r.count--
+	}
+}
+
+func (r *ackRing) grow() {
+	grown := make([]*util.BitSet, len(r.entries)*2)
+	for i := 0; i < r.count; i++ {
+		grown[i] = r.entries[(r.head+i)%len(r.entries)]
+	}
+	r.entries = grown
+	r.head = 0
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"testing"
+
+	"github.com/streamnative/oxia/server/util"
+)
+
+func TestAckRing_PushGetRemove(t *testing.T) {
+	r := newAckRing()
+
+	for offset := int64(1); offset <= 5; offset++ {
+		r.Push(offset, &util.BitSet{})
+	}
+
+	if _, found := r.Get(0); found {
+		t.Error("expected offset 0 to not be tracked")
+	}
+	if _, found := r.Get(6); found {
+		t.Error("expected offset 6 to not be tracked")
+	}
+	for offset := int64(1); offset <= 5; offset++ {
+		if _, found := r.Get(offset); !found {
+			t.Errorf("expected offset %d to be tracked", offset)
+		}
+	}
+
+	// Remove out of order: offset 3 first, which should just leave a hole without
+	// sliding the window, since offset 1 is still the oldest pending entry.
+	r.Remove(3)
+	if _, found := r.Get(3); found {
+		t.Error("expected offset 3 to have been removed")
+	}
+	if r.count != 5 {
+		t.Errorf("expected the window to still span 5 slots (with a hole), got %d", r.count)
+	}
+
+	// Now remove offsets 1 and 2: the window should slide forward past them *and* past
+	// the already-removed offset 3, landing on offset 4.
+	r.Remove(1)
+	r.Remove(2)
+	if r.base != 4 {
+		t.Errorf("expected the window to slide forward to base=4, got %d", r.base)
+	}
+	if r.count != 2 {
+		t.Errorf("expected 2 remaining tracked offsets (4, 5), got %d", r.count)
+	}
+}
+
+// TestAckRing_LiveTracksOutOfOrderRemovals checks that Live() reflects offsets that are
+// genuinely still pending quorum, unlike count which also counts holes left behind by
+// offsets removed out of order that haven't slid out of the window yet.
+func TestAckRing_LiveTracksOutOfOrderRemovals(t *testing.T) {
+	r := newAckRing()
+
+	for offset := int64(1); offset <= 10; offset++ {
+		r.Push(offset, &util.BitSet{})
+	}
+	if r.Live() != 10 {
+		t.Fatalf("expected 10 live entries after pushing 10, got %d", r.Live())
+	}
+
+	// Remove offset 5 out of order: it's not at head, so count doesn't shrink, but Live()
+	// must still drop since offset 5 is no longer actually pending.
+	r.Remove(5)
+	if r.count != 10 {
+		t.Fatalf("expected count to still be 10 (a hole at offset 5), got %d", r.count)
+	}
+	if r.Live() != 9 {
+		t.Fatalf("expected 9 live entries after removing offset 5 out of order, got %d", r.Live())
+	}
+
+	// Removing the same offset again must not double-decrement.
+	r.Remove(5)
+	if r.Live() != 9 {
+		t.Fatalf("expected removing an already-removed offset to be a no-op, got %d live", r.Live())
+	}
+}
+
+func TestAckRing_GrowsPastInitialCapacity(t *testing.T) {
+	r := newAckRing()
+
+	for offset := int64(1); offset <= ackRingInitialCapacity+10; offset++ {
+		r.Push(offset, &util.BitSet{})
+	}
+
+	for offset := int64(1); offset <= ackRingInitialCapacity+10; offset++ {
+		if _, found := r.Get(offset); !found {
+			t.Fatalf("expected offset %d to be tracked after growing", offset)
+		}
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"log/slog"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/streamnative/oxia/common"
 	"github.com/streamnative/oxia/server/util"
@@ -67,16 +68,20 @@ type QuorumAckTracker interface {
 
 	// NewCursorAcker creates a tracker for a new cursor
 	// The `ackOffset` is the previous last-acked position for the cursor
-	NewCursorAcker(ackOffset int64) (CursorAcker, error)
+	// `spec` configures the cursor's weight and whether it is a witness, for use by
+	// the tracker's QuorumPolicy.
+	NewCursorAcker(ackOffset int64, spec CursorSpec) (CursorAcker, error)
 }
 
+const ackRangeUnset = -1
+
 type quorumAckTracker struct {
 	sync.Mutex
 	waitingRequests   []waitingRequest
 	waitForHeadOffset common.ConditionContext
 
 	replicationFactor uint32
-	requiredAcks      uint32
+	policy            QuorumPolicy
 
 	nextOffset   atomic.Int64
 	headOffset   atomic.Int64
@@ -84,18 +89,45 @@ type quorumAckTracker struct {
 
 	// Keep track of the number of acks that each entry has received
 	// The bitset is used to handle duplicate acks from a single follower
-	tracker            map[int64]*util.BitSet
+	tracker *ackRing
+
+	// cursorWeights and cursorWitness are indexed by cursor index and mirror the
+	// CursorSpec each cursor was created with, for consumption by the QuorumPolicy
+	cursorWeights []int
+	cursorWitness []bool
+
 	cursorIdxGenerator int
-	closed             bool
+	closed             atomic.Bool
+
+	// metrics is nil unless the tracker was created with NewQuorumAckTrackerWithMetrics.
+	metrics *quorumAckTrackerMetrics
+	// addedAt records when each offset still in `tracker` started being tracked, so that
+	// metrics can report the ack-to-commit latency. Only populated when metrics is non-nil.
+	addedAt map[int64]time.Time
 }
 
 type CursorAcker interface {
 	Ack(offset int64)
+
+	// AckRange acks every offset in [from, to] in a single tracker lock acquisition.
+	// Followers replicate in contiguous batches, so callers should prefer this over
+	// repeated Ack calls on the hot path.
+	AckRange(from, to int64)
+
+	// Witness reports whether this cursor was created with CursorSpec.Witness set, ie.
+	// it acks offsets without storing the corresponding entries.
+	Witness() bool
 }
 
 type cursorAcker struct {
 	quorumTracker *quorumAckTracker
 	cursorIdx     int
+	witness       bool
+
+	// lastAcked is the cursor's high-water mark: the last offset it has acked. It lets
+	// AckRange skip already-seen offsets and detect a fully redundant range without ever
+	// taking the tracker lock.
+	lastAcked atomic.Int64
 }
 
 type waitingRequest struct {
@@ -103,13 +135,22 @@ type waitingRequest struct {
 	closure   func(ctx context.Context, err error)
 }
 
+// NewQuorumAckTracker creates a QuorumAckTracker using the default majority QuorumPolicy,
+// where an entry is committed once it has acks from a plain majority of the replication
+// factor. Use NewQuorumAckTrackerWithPolicy to configure a different policy, e.g. flexible/
+// grid quorums or witness replicas.
 func NewQuorumAckTracker(replicationFactor uint32, headOffset int64, commitOffset int64) QuorumAckTracker {
+	return NewQuorumAckTrackerWithPolicy(replicationFactor, headOffset, commitOffset, NewMajorityQuorumPolicy(replicationFactor))
+}
+
+// NewQuorumAckTrackerWithPolicy creates a QuorumAckTracker whose commit-offset advancement
+// is driven by the given QuorumPolicy instead of a plain majority, so that operators can
+// configure flexible/grid quorums or witness replicas without recompiling.
+func NewQuorumAckTrackerWithPolicy(replicationFactor uint32, headOffset int64, commitOffset int64, policy QuorumPolicy) QuorumAckTracker {
 	q := &quorumAckTracker{
-		// Ack quorum is number of follower acks that are required to consider the entry fully committed
-		// We are using RF/2 (and not RF/2 + 1) because the leader is already storing 1 copy locally
-		requiredAcks:      replicationFactor / 2,
 		replicationFactor: replicationFactor,
-		tracker:           make(map[int64]*util.BitSet),
+		policy:            policy,
+		tracker:           newAckRing(),
 		waitingRequests:   make([]waitingRequest, 0),
 	}
 
@@ -119,29 +160,90 @@ func NewQuorumAckTracker(replicationFactor uint32, headOffset int64, commitOffse
 
 	// Add entries to track the entries we're not yet sure that are fully committed
 	for offset := commitOffset + 1; offset <= headOffset; offset++ {
-		q.tracker[offset] = &util.BitSet{}
+		q.tracker.Push(offset, &util.BitSet{})
 	}
 
 	q.waitForHeadOffset = common.NewConditionContext(q)
 	return q
 }
 
+// NewQuorumAckTrackerWithMetrics is like NewQuorumAckTracker, but also registers OTel
+// metrics for the tracker, labelled with shardID: gauges for the head/commit offsets and
+// how far apart they are, the number of pending waiters and in-flight tracker entries, a
+// histogram of ack-to-commit latency, and per-cursor ack counters and lag gauges.
+func NewQuorumAckTrackerWithMetrics(shardID string, replicationFactor uint32, headOffset int64, commitOffset int64) QuorumAckTracker {
+	return NewQuorumAckTrackerWithMetricsAndPolicy(shardID, replicationFactor, headOffset, commitOffset, NewMajorityQuorumPolicy(replicationFactor))
+}
+
+// NewQuorumAckTrackerWithMetricsAndPolicy combines NewQuorumAckTrackerWithMetrics and
+// NewQuorumAckTrackerWithPolicy, for when a tracker needs both OTel metrics and a custom
+// QuorumPolicy (eg. a weighted policy or witness replicas) at the same time.
+func NewQuorumAckTrackerWithMetricsAndPolicy(shardID string, replicationFactor uint32, headOffset int64, commitOffset int64, policy QuorumPolicy) QuorumAckTracker {
+	q := NewQuorumAckTrackerWithPolicy(replicationFactor, headOffset, commitOffset, policy).(*quorumAckTracker)
+
+	q.metrics = newQuorumAckTrackerMetrics(shardID)
+	q.addedAt = make(map[int64]time.Time)
+
+	q.Lock()
+	for offset := commitOffset + 1; offset <= headOffset; offset++ {
+		q.addedAt[offset] = time.Now()
+	}
+	q.refreshMetrics()
+	q.Unlock()
+
+	return q
+}
+
 func (q *quorumAckTracker) AdvanceHeadOffset(headOffset int64) {
 	q.Lock()
 	defer q.Unlock()
 
-	if headOffset <= q.headOffset.Load() {
+	previousHeadOffset := q.headOffset.Load()
+	if headOffset <= previousHeadOffset {
 		return
 	}
 
 	q.headOffset.Store(headOffset)
 	q.waitForHeadOffset.Broadcast()
 
-	if q.requiredAcks == 0 {
+	if q.quorumSatisfied(&util.BitSet{}) {
 		q.notifyCommitOffsetAdvanced(headOffset)
 	} else {
-		q.tracker[headOffset] = &util.BitSet{}
+		// headOffset can jump ahead by more than one entry (eg. a batched AckRange call
+		// catching up a follower), so every offset in between needs its own tracked
+		// BitSet, not just the new head: otherwise quorum resolution for the skipped
+		// offsets is silently lost, and acks for them are mistaken for no-ops.
+		for offset := previousHeadOffset + 1; offset <= headOffset; offset++ {
+			q.tracker.Push(offset, &util.BitSet{})
+			if q.metrics != nil {
+				q.addedAt[offset] = time.Now()
+			}
+		}
+	}
+
+	q.refreshMetrics()
+}
+
+// quorumSatisfied reports whether `acks` both satisfies the configured QuorumPolicy and
+// includes at least one ack from a non-witness (data-storing) cursor, so that a quorum
+// made up entirely of witness replicas can never be mistaken for durably stored data.
+func (q *quorumAckTracker) quorumSatisfied(acks *util.BitSet) bool {
+	return q.policy.Satisfied(acks, q.cursorWeights) && q.hasDataAck(acks)
+}
+
+// hasDataAck reports whether at least one non-witness cursor has acked, per `acks`. If no
+// cursor has been created yet (eg. a replication-factor-1 tracker with no followers at
+// all), there's no witness concept in play, so it trivially holds.
+func (q *quorumAckTracker) hasDataAck(acks *util.BitSet) bool {
+	if len(q.cursorWitness) == 0 {
+		return true
+	}
+	for idx, witness := range q.cursorWitness {
+		if !witness && acks.IsSet(idx) {
+			return true
+		}
 	}
+	return false
 }
 
 func (q *quorumAckTracker) NextOffset() int64 {
@@ -160,7 +262,7 @@ func (q *quorumAckTracker) WaitForHeadOffset(ctx context.Context, offset int64)
 	q.Lock()
 	defer q.Unlock()
 
-	for !q.closed && q.headOffset.Load() < offset {
+	for !q.closed.Load() && q.headOffset.Load() < offset {
 		if err := q.waitForHeadOffset.Wait(ctx); err != nil {
 			return err
 		}
@@ -193,18 +295,28 @@ func (q *quorumAckTracker) WaitForCommitOffset(ctx context.Context, offset int64
 }
 
 func (q *quorumAckTracker) WaitForCommitOffsetAsync(ctx context.Context, offset int64, closure func(context.Context, error)) {
+	// Fast path: the overwhelmingly common case is that the requested offset is already
+	// committed, so check that lock-free before paying for the mutex. Once closed, every
+	// call must uniformly get ErrorAlreadyClosed, so this still defers to the closed check
+	// below instead of short-circuiting on a closed tracker.
+	if !q.closed.Load() && q.commitOffset.Load() >= offset {
+		closure(ctx, nil)
+		return
+	}
+
 	q.Lock()
-	if q.closed {
+	if q.closed.Load() {
 		q.Unlock()
 		closure(ctx, common.ErrorAlreadyClosed)
 		return
 	}
-	if q.requiredAcks == 0 || q.commitOffset.Load() >= offset {
+	if q.quorumSatisfied(&util.BitSet{}) || q.commitOffset.Load() >= offset {
 		q.Unlock()
 		closure(ctx, nil)
 		return
 	}
 	q.waitingRequests = append(q.waitingRequests, waitingRequest{offset, closure})
+	q.refreshMetrics()
 	q.Unlock()
 }
 
@@ -225,12 +337,12 @@ func (q *quorumAckTracker) Close() error {
 	q.Lock()
 	defer q.Unlock()
 
-	q.closed = true
+	q.closed.Store(true)
 	q.waitForHeadOffset.Broadcast()
 	return nil
 }
 
-func (q *quorumAckTracker) NewCursorAcker(ackOffset int64) (CursorAcker, error) {
+func (q *quorumAckTracker) NewCursorAcker(ackOffset int64, spec CursorSpec) (CursorAcker, error) {
 	q.Lock()
 	defer q.Unlock()
 
@@ -242,9 +354,22 @@ func (q *quorumAckTracker) NewCursorAcker(ackOffset int64) (CursorAcker, error)
 		return nil, ErrInvalidHeadOffset
 	}
 
+	weight := spec.Weight
+	if weight <= 0 {
+		weight = 1
+	}
+
 	qa := &cursorAcker{
 		quorumTracker: q,
 		cursorIdx:     q.cursorIdxGenerator,
+		witness:       spec.Witness,
+	}
+	qa.lastAcked.Store(ackRangeUnset)
+
+	q.cursorWeights = append(q.cursorWeights, weight)
+	q.cursorWitness = append(q.cursorWitness, spec.Witness)
+	if q.metrics != nil {
+		q.metrics.addCursor(qa.cursorIdx)
 	}
 
 	// If the new cursor is already past the current quorum commit offset, we have
@@ -252,22 +377,42 @@ func (q *quorumAckTracker) NewCursorAcker(ackOffset int64) (CursorAcker, error)
 	for offset := q.commitOffset.Load() + 1; offset <= ackOffset; offset++ {
 		qa.ack(offset)
 	}
+	qa.lastAcked.Store(ackOffset)
 
 	q.cursorIdxGenerator++
 	return qa, nil
 }
 
+func (c *cursorAcker) Witness() bool {
+	return c.witness
+}
+
 func (c *cursorAcker) Ack(offset int64) {
+	c.AckRange(offset, offset)
+}
+
+func (c *cursorAcker) AckRange(from, to int64) {
+	if to <= c.lastAcked.Load() {
+		// Nothing new in this range: the cursor has already acked up to (at least) `to`.
+		return
+	}
+
 	c.quorumTracker.Lock()
 	defer c.quorumTracker.Unlock()
 
-	c.ack(offset)
+	if last := c.lastAcked.Load(); from <= last {
+		from = last + 1
+	}
+	for offset := from; offset <= to; offset++ {
+		c.ack(offset)
+	}
+	c.lastAcked.Store(to)
 }
 
 func (c *cursorAcker) ack(offset int64) {
 	q := c.quorumTracker
 
-	e, found := q.tracker[offset]
+	e, found := q.tracker.Get(offset)
 	if !found {
 		// The entry has already previously reached the quorum.
 		// There's nothing more left to do here.
@@ -276,10 +421,24 @@ func (c *cursorAcker) ack(offset int64) {
 
 	// Mark that this follower has acked the entry
 	e.Set(c.cursorIdx)
-	if uint32(e.Count()) == q.requiredAcks {
-		delete(q.tracker, offset)
+
+	if q.metrics != nil {
+		q.metrics.cursorAcks[c.cursorIdx].Add(1)
+		q.metrics.cursorLag[c.cursorIdx].Set(q.headOffset.Load() - offset)
+	}
+
+	if q.quorumSatisfied(e) {
+		q.tracker.Remove(offset)
+
+		if q.metrics != nil {
+			if addedAt, ok := q.addedAt[offset]; ok {
+				q.metrics.ackToCommitLatency.Record(float64(time.Since(addedAt).Milliseconds()))
+				delete(q.addedAt, offset)
+			}
+		}
 
 		// Advance the commit offset
 		q.notifyCommitOffsetAdvanced(offset)
+		q.refreshMetrics()
 	}
 }
@@ -0,0 +1,75 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import "github.com/streamnative/oxia/common/metrics"
+
+// quorumAckTrackerMetrics holds the OTel instruments for a single shard's
+// QuorumAckTracker. It's only populated when the tracker is created through
+// NewQuorumAckTrackerWithMetrics; a nil *quorumAckTrackerMetrics means metrics are
+// disabled, and every call site checks for that before touching it.
+type quorumAckTrackerMetrics struct {
+	headOffset             metrics.Gauge
+	commitOffset           metrics.Gauge
+	headMinusCommitLag     metrics.Gauge
+	pendingWaiters         metrics.Gauge
+	inFlightTrackerEntries metrics.Gauge
+	ackToCommitLatency     metrics.LatencyHistogram
+
+	// cursorAcks and cursorLag are indexed by cursor index, mirroring cursorWeights.
+	cursorAcks []metrics.Counter
+	cursorLag  []metrics.Gauge
+
+	shardID string
+}
+
+func newQuorumAckTrackerMetrics(shardID string) *quorumAckTrackerMetrics {
+	labels := metrics.Labels{"shard": shardID}
+	return &quorumAckTrackerMetrics{
+		headOffset:             metrics.NewGauge("oxia_server_quorum_head_offset", "The head offset of the shard", metrics.Dimensionless, labels),
+		commitOffset:           metrics.NewGauge("oxia_server_quorum_commit_offset", "The commit offset of the shard", metrics.Dimensionless, labels),
+		headMinusCommitLag:     metrics.NewGauge("oxia_server_quorum_head_minus_commit_lag", "The number of entries written but not yet committed", metrics.Dimensionless, labels),
+		pendingWaiters:         metrics.NewGauge("oxia_server_quorum_pending_waiters", "The number of callers waiting for the commit offset to advance", metrics.Dimensionless, labels),
+		inFlightTrackerEntries: metrics.NewGauge("oxia_server_quorum_in_flight_tracker_entries", "The number of entries currently tracked waiting for quorum", metrics.Dimensionless, labels),
+		ackToCommitLatency:     metrics.NewLatencyHistogram("oxia_server_quorum_ack_to_commit_latency", "The time between an entry being tracked and it reaching quorum", labels),
+		shardID:                shardID,
+	}
+}
+
+// addCursor registers the metrics for a newly created cursor. Must be called with the
+// tracker lock held, and exactly once per cursor index, in order.
+func (m *quorumAckTrackerMetrics) addCursor(cursorIdx int) {
+	labels := metrics.Labels{"shard": m.shardID, "cursor": cursorIdx}
+	m.cursorAcks = append(m.cursorAcks, metrics.NewCounter("oxia_server_quorum_cursor_acks", "The number of acks received from this cursor", metrics.Dimensionless, labels))
+	m.cursorLag = append(m.cursorLag, metrics.NewGauge("oxia_server_quorum_cursor_lag", "The number of entries this cursor is behind the head offset", metrics.Dimensionless, labels))
+}
+
+// refreshMetrics updates the gauges that reflect the tracker's current state. It's cheap enough
+// to call on every mutation, since it's just a handful of atomic/gauge writes guarded by
+// the tracker lock the caller already holds.
+func (q *quorumAckTracker) refreshMetrics() {
+	if q.metrics == nil {
+		return
+	}
+
+	head := q.headOffset.Load()
+	commit := q.commitOffset.Load()
+
+	q.metrics.headOffset.Set(head)
+	q.metrics.commitOffset.Set(commit)
+	q.metrics.headMinusCommitLag.Set(head - commit)
+	q.metrics.pendingWaiters.Set(int64(len(q.waitingRequests)))
+	q.metrics.inFlightTrackerEntries.Set(int64(q.tracker.Live()))
+}
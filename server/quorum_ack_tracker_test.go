@@ -0,0 +1,115 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/streamnative/oxia/common"
+)
+
+// TestQuorumAckTracker_BatchedHeadAdvanceTracksEveryOffset reproduces the scenario where
+// AdvanceHeadOffset jumps the head offset forward by more than one entry (as
+// BenchmarkQuorumAckTracker_AckRange does): every offset in between must still get its own
+// tracked BitSet, so that a slower cursor acking only part of the range doesn't silently
+// block commit-offset advancement for offsets the quorum has already reached.
+func TestQuorumAckTracker_BatchedHeadAdvanceTracksEveryOffset(t *testing.T) {
+	// requiredAcks = 2 (majority of 5), so both follower cursors below must ack a given
+	// offset for it to reach quorum.
+	q := NewQuorumAckTracker(5, 0, 0)
+	defer q.Close()
+
+	cursorA, err := q.NewCursorAcker(0, CursorSpec{Weight: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursorB, err := q.NewCursorAcker(0, CursorSpec{Weight: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Head jumps forward by 100 in one call, as a batched replication round would.
+	q.AdvanceHeadOffset(100)
+
+	// Cursor A (fast) replicates and acks the whole batch; cursor B (slow) only
+	// manages the first half.
+	cursorA.AckRange(1, 100)
+	cursorB.AckRange(1, 50)
+
+	if got := q.CommitOffset(); got != 50 {
+		t.Fatalf("expected commit offset to advance to 50 (quorum reached for 1-50 via both cursors), got %d", got)
+	}
+
+	cursorB.AckRange(51, 100)
+	if got := q.CommitOffset(); got != 100 {
+		t.Fatalf("expected commit offset to advance to 100 once cursor B catches up, got %d", got)
+	}
+}
+
+// TestNewQuorumAckTrackerWithMetricsAndPolicy checks that a tracker created with metrics
+// enabled still honours the QuorumPolicy it was given, rather than silently falling back
+// to the majority policy NewQuorumAckTrackerWithMetrics defaults to.
+func TestNewQuorumAckTrackerWithMetricsAndPolicy(t *testing.T) {
+	q := NewQuorumAckTrackerWithMetricsAndPolicy("test-shard", 5, 0, 0, NewWeightedQuorumPolicy(2))
+	defer q.Close()
+
+	cursor, err := q.NewCursorAcker(0, CursorSpec{Weight: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q.AdvanceHeadOffset(1)
+	cursor.Ack(1)
+
+	// A single cursor with weight 2 already meets the required weight of 2, while the
+	// majority policy for 5 replicas would need 2 acks, so the weighted policy must be the
+	// one in effect.
+	if got := q.CommitOffset(); got != 1 {
+		t.Fatalf("expected the weighted policy to be used, got commit offset %d", got)
+	}
+}
+
+// TestQuorumAckTracker_WaitForCommitOffsetAsyncAfterCloseAlwaysErrors checks that once the
+// tracker is closed, every call to WaitForCommitOffsetAsync gets ErrorAlreadyClosed, even
+// for an offset that was already committed before Close was called: the lock-free fast
+// path must not bypass the closed check.
+func TestQuorumAckTracker_WaitForCommitOffsetAsyncAfterCloseAlwaysErrors(t *testing.T) {
+	q := NewQuorumAckTracker(3, 0, 0)
+
+	cursor, err := q.NewCursorAcker(0, CursorSpec{Weight: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q.AdvanceHeadOffset(1)
+	cursor.Ack(1)
+	if got := q.CommitOffset(); got != 1 {
+		t.Fatalf("expected offset 1 to be committed before closing, got commit offset %d", got)
+	}
+
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotErr error
+	q.WaitForCommitOffsetAsync(context.Background(), 1, func(_ context.Context, err error) {
+		gotErr = err
+	})
+	if !errors.Is(gotErr, common.ErrorAlreadyClosed) {
+		t.Fatalf("expected ErrorAlreadyClosed for an already-committed offset after Close, got %v", gotErr)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import "github.com/streamnative/oxia/server/util"
+
+// QuorumPolicy decides whether the acks recorded for a given entry are enough to consider
+// that entry fully committed. Implementations are consulted by the QuorumAckTracker every
+// time a cursor acks an entry, and once more for the "no ack required" fast path (an empty
+// bitset with no cursors having acked yet).
+type QuorumPolicy interface {
+	// Satisfied reports whether `acks` represents a quorum for the entry it was recorded for.
+	// `cursorWeights` is indexed by cursor index and holds the weight that each cursor
+	// contributes towards the quorum, as configured through CursorSpec.
+	Satisfied(acks *util.BitSet, cursorWeights []int) bool
+}
+
+// CursorSpec configures a cursor created through QuorumAckTracker.NewCursorAcker.
+type CursorSpec struct {
+	// Weight is the number of votes this cursor contributes towards the configured
+	// QuorumPolicy. A zero value defaults to 1, matching a plain majority quorum.
+	Weight int
+
+	// Witness marks a cursor that participates in acking offsets but does not store the
+	// associated entries. Witness cursors let a cluster run with a cheaper replica
+	// (e.g. a 2+1 data+witness configuration) while still contributing to the quorum;
+	// the tracker still requires at least one non-witness ack before committing an
+	// entry, so an all-witness quorum is never mistaken for durably stored data.
+	Witness bool
+}
+
+type majorityQuorumPolicy struct {
+	requiredAcks uint32
+}
+
+// NewMajorityQuorumPolicy returns the default QuorumPolicy: an entry is committed once it has
+// received acks from a plain majority of the replication factor, ignoring cursor weights.
+// We use RF/2 (and not RF/2 + 1) because the leader is already storing 1 copy locally.
+func NewMajorityQuorumPolicy(replicationFactor uint32) QuorumPolicy {
+	return &majorityQuorumPolicy{requiredAcks: replicationFactor / 2}
+}
+
+func (p *majorityQuorumPolicy) Satisfied(acks *util.BitSet, _ []int) bool {
+	return uint32(acks.Count()) >= p.requiredAcks
+}
+
+type weightedQuorumPolicy struct {
+	requiredWeight int
+}
+
+// NewWeightedQuorumPolicy returns a QuorumPolicy for flexible/grid quorums: an entry is
+// committed once the sum of the weights of the cursors that acked it reaches
+// requiredWeight. Operators can use this for R+W>N style tuning, or to let witness
+// replicas (CursorSpec.Witness) cast votes without holding the data.
+func NewWeightedQuorumPolicy(requiredWeight int) QuorumPolicy {
+	return &weightedQuorumPolicy{requiredWeight: requiredWeight}
+}
+
+func (p *weightedQuorumPolicy) Satisfied(acks *util.BitSet, cursorWeights []int) bool {
+	weight := 0
+	for idx, w := range cursorWeights {
+		if acks.IsSet(idx) {
+			weight += w
+		}
+	}
+	return weight >= p.requiredWeight
+}
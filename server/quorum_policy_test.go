@@ -0,0 +1,93 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"testing"
+
+	"github.com/streamnative/oxia/server/util"
+)
+
+func bitSetOf(idxs ...int) *util.BitSet {
+	bs := &util.BitSet{}
+	for _, idx := range idxs {
+		bs.Set(idx)
+	}
+	return bs
+}
+
+func TestMajorityQuorumPolicy(t *testing.T) {
+	policy := NewMajorityQuorumPolicy(3) // requiredAcks = 1
+
+	if policy.Satisfied(bitSetOf(), nil) {
+		t.Error("expected no acks to not satisfy a majority of 3")
+	}
+	if !policy.Satisfied(bitSetOf(0), nil) {
+		t.Error("expected a single ack to satisfy a majority of 3")
+	}
+}
+
+func TestWeightedQuorumPolicy(t *testing.T) {
+	policy := NewWeightedQuorumPolicy(3)
+	weights := []int{1, 2, 1}
+
+	if policy.Satisfied(bitSetOf(0), weights) {
+		t.Error("expected weight 1 to not satisfy a required weight of 3")
+	}
+	if !policy.Satisfied(bitSetOf(0, 1), weights) {
+		t.Error("expected weight 1+2=3 to satisfy a required weight of 3")
+	}
+	if !policy.Satisfied(bitSetOf(1, 2), weights) {
+		t.Error("expected weight 2+1=3 to satisfy a required weight of 3")
+	}
+}
+
+// TestQuorumAckTracker_WitnessOnlyQuorumDoesNotCommit verifies that CursorSpec.Witness is
+// actually enforced: a quorum made up entirely of witness cursors must not be treated as
+// committed, since none of them actually store the entry.
+func TestQuorumAckTracker_WitnessOnlyQuorumDoesNotCommit(t *testing.T) {
+	// Weighted policy requiring just 1 vote, so a single witness ack alone would
+	// otherwise be enough to "commit" the entry.
+	q := NewQuorumAckTrackerWithPolicy(3, 0, 0, NewWeightedQuorumPolicy(1))
+	defer q.Close()
+
+	witness, err := q.NewCursorAcker(0, CursorSpec{Weight: 1, Witness: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !witness.Witness() {
+		t.Error("expected the cursor to report itself as a witness")
+	}
+
+	q.AdvanceHeadOffset(1)
+	witness.Ack(1)
+
+	if q.CommitOffset() != 0 {
+		t.Errorf("expected commit offset to stay at 0 with only a witness ack, got %d", q.CommitOffset())
+	}
+
+	data, err := q.NewCursorAcker(0, CursorSpec{Weight: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Witness() {
+		t.Error("expected the cursor to report itself as a non-witness")
+	}
+	data.Ack(1)
+
+	if q.CommitOffset() != 1 {
+		t.Errorf("expected commit offset to advance to 1 once a non-witness cursor acked, got %d", q.CommitOffset())
+	}
+}